@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/session"
+)
+
+// sessionTokenTTL is how long a token minted for a newly created client stays valid.
+const sessionTokenTTL = 24 * time.Hour
+
+// sessionRepo persists and validates the bearer tokens minted on client creation.
+var sessionRepo *session.SessionKeyRepo
+
+// setupSession wires a SessionKeyRepo to db.
+func setupSession() {
+	sessionRepo = session.NewSessionKeyRepo(db)
+}
+
+// mintSessionToken generates a new bearer token for clientID, valid for sessionTokenTTL.
+func mintSessionToken(clientID int) (string, error) {
+	token := uuid.New().String()
+	if err := sessionRepo.Push(session.SessionKey{Key: token, ClientID: clientID}, sessionTokenTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
@@ -4,18 +4,25 @@ import (
 	"time"
 )
 
-// Client represents a client in the system.
+// Client represents a client in the system. ID is the internal autoincrement
+// primary key and is never exposed over the API; callers address a client by
+// its UUID instead.
 type Client struct {
-	ID            int    `json:"id"`
+	ID            int    `json:"-"`
+	UUID          string `json:"id"`
 	Name          string `json:"name"`
 	WorkingHours  string `json:"working_hours"`
 	Priority      int    `json:"priority"`
 	LeadCapacity  int    `json:"lead_capacity"`
 	ExistingLeads int    `json:"existing_leads"`
+	WebhookURL    string `json:"webhook_url"`
 }
 
 // TimePeriod represents a period of time with a start and end time. The start and end times are of type time.Time.
+// IANA is the timezone the period was parsed in ("" when the wire format omitted one, in which case time.Local was used).
+// If End is before Start, the period crosses midnight.
 type TimePeriod struct {
 	Start time.Time
 	End   time.Time
+	IANA  string
 }
@@ -0,0 +1,103 @@
+// Package dispatcher delivers assigned-lead payloads to a client's webhook over HTTP,
+// retrying transient failures with exponential backoff and marking a client unhealthy
+// after too many consecutive failures so assignLead can skip it.
+package dispatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout is how long a single dispatch attempt waits for a response.
+const DefaultTimeout = 10 * time.Second
+
+const (
+	defaultFailureThreshold = 3
+	defaultMaxRetries       = 3
+	defaultBackoff          = 500 * time.Millisecond
+)
+
+// ErrClientUnhealthy is returned by Dispatch when the client has already tripped its
+// failure threshold and is waiting on a healthcheck probe to recover.
+var ErrClientUnhealthy = errors.New("client is unhealthy")
+
+// Dispatcher POSTs lead payloads to client webhooks.
+type Dispatcher struct {
+	Timeout          time.Duration
+	FailureThreshold int
+	MaxRetries       int
+
+	health *HealthRepo
+}
+
+// NewDispatcher returns a Dispatcher with the package defaults, backed by health for
+// per-client failure tracking.
+func NewDispatcher(health *HealthRepo) *Dispatcher {
+	return &Dispatcher{
+		Timeout:          DefaultTimeout,
+		FailureThreshold: defaultFailureThreshold,
+		MaxRetries:       defaultMaxRetries,
+		health:           health,
+	}
+}
+
+// Dispatch POSTs payload as JSON to endpoint on behalf of clientID. It retries a failed
+// attempt with exponential backoff up to MaxRetries times; a 5xx response or a timeout
+// counts as a failure. If every attempt fails, the client's consecutive failure count is
+// incremented and, once it reaches FailureThreshold, the client is marked unhealthy.
+// Dispatch refuses to even try a client that is already unhealthy.
+func (d *Dispatcher) Dispatch(clientID int, endpoint string, payload any) error {
+	healthy, err := d.health.IsHealthy(clientID)
+	if err != nil {
+		return fmt.Errorf("check client health: %w", err)
+	}
+	if !healthy {
+		return ErrClientUnhealthy
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal dispatch payload: %w", err)
+	}
+
+	client := &http.Client{Timeout: d.Timeout}
+	backoff := defaultBackoff
+
+	var lastErr error
+	for attempt := 0; attempt <= d.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("build dispatch request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+			continue
+		}
+
+		return d.health.RecordSuccess(clientID)
+	}
+
+	if err := d.health.RecordFailure(clientID, d.FailureThreshold); err != nil {
+		return fmt.Errorf("record dispatch failure: %w", err)
+	}
+
+	return fmt.Errorf("dispatch to %s failed after %d attempts: %w", endpoint, d.MaxRetries+1, lastErr)
+}
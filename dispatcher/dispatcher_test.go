@@ -0,0 +1,98 @@
+package dispatcher
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestHealthRepo(t *testing.T) *HealthRepo {
+	t.Helper()
+
+	path := "./test-client-health.db"
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE client_health (
+		client_id INTEGER PRIMARY KEY,
+		healthy BOOLEAN NOT NULL DEFAULT 1,
+		last_probe_at DATETIME,
+		consecutive_failures INTEGER NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(path)
+	})
+
+	return NewHealthRepo(db)
+}
+
+// TestDispatchStopsAfterThresholdAndResumesAfterRecovery stands up a flaky upstream that
+// fails every request until healthy is flipped, and asserts the dispatcher stops routing
+// to it once FailureThreshold consecutive failures have been seen, then resumes once a
+// healthcheck probe against the now-recovered upstream succeeds.
+func TestDispatchStopsAfterThresholdAndResumesAfterRecovery(t *testing.T) {
+	health := newTestHealthRepo(t)
+	const clientID = 1
+
+	healthy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(health)
+	d.FailureThreshold = 2
+	d.MaxRetries = 0 // each Dispatch call is exactly one attempt, so every call counts as one failure
+
+	if err := d.Dispatch(clientID, server.URL, map[string]string{"lead": "1"}); err == nil {
+		t.Fatal("expected first dispatch to the flaky upstream to fail")
+	}
+	if ok, _ := health.IsHealthy(clientID); !ok {
+		t.Fatal("client should still be healthy after one failure")
+	}
+
+	if err := d.Dispatch(clientID, server.URL, map[string]string{"lead": "2"}); err == nil {
+		t.Fatal("expected second dispatch to the flaky upstream to fail")
+	}
+	if ok, _ := health.IsHealthy(clientID); ok {
+		t.Fatal("client should be unhealthy after reaching the failure threshold")
+	}
+
+	if err := d.Dispatch(clientID, server.URL, map[string]string{"lead": "3"}); err != ErrClientUnhealthy {
+		t.Fatalf("expected dispatch to a known-unhealthy client to be skipped, got: %v", err)
+	}
+
+	healthy = true
+	checker := NewHealthChecker(health, time.Second, func() ([]Target, error) {
+		return []Target{{ClientID: clientID, Endpoint: server.URL}}, nil
+	})
+	checker.probeUnhealthy()
+
+	if ok, _ := health.IsHealthy(clientID); !ok {
+		t.Fatal("client should be healthy again after a successful probe")
+	}
+
+	if err := d.Dispatch(clientID, server.URL, map[string]string{"lead": "4"}); err != nil {
+		t.Fatalf("expected dispatch to recovered upstream to succeed, got: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package dispatcher
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Health is the health state the dispatcher tracks for a single client.
+type Health struct {
+	ClientID            int        `json:"client_id"`
+	Healthy             bool       `json:"healthy"`
+	LastProbeAt         *time.Time `json:"last_probe_at"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// HealthRepo persists Health in the client_health table.
+type HealthRepo struct {
+	db *sql.DB
+}
+
+// NewHealthRepo returns a HealthRepo backed by db.
+func NewHealthRepo(db *sql.DB) *HealthRepo {
+	return &HealthRepo{db: db}
+}
+
+// IsHealthy reports whether clientID is currently considered healthy. A client with no
+// recorded health state yet is treated as healthy.
+func (r *HealthRepo) IsHealthy(clientID int) (bool, error) {
+	row := r.db.QueryRow("SELECT healthy FROM client_health WHERE client_id = ?", clientID)
+	var healthy bool
+	if err := row.Scan(&healthy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+		return false, fmt.Errorf("read client health: %w", err)
+	}
+	return healthy, nil
+}
+
+// Get returns the full health record for clientID, or the zero Health with Healthy true
+// if none has been recorded yet.
+func (r *HealthRepo) Get(clientID int) (Health, error) {
+	health := Health{ClientID: clientID, Healthy: true}
+
+	row := r.db.QueryRow("SELECT healthy, last_probe_at, consecutive_failures FROM client_health WHERE client_id = ?", clientID)
+	var lastProbeAt sql.NullTime
+	err := row.Scan(&health.Healthy, &lastProbeAt, &health.ConsecutiveFailures)
+	if errors.Is(err, sql.ErrNoRows) {
+		return health, nil
+	}
+	if err != nil {
+		return Health{}, fmt.Errorf("read client health: %w", err)
+	}
+	if lastProbeAt.Valid {
+		health.LastProbeAt = &lastProbeAt.Time
+	}
+
+	return health, nil
+}
+
+// RecordFailure increments clientID's consecutive failure count and marks it unhealthy
+// once that count reaches threshold.
+func (r *HealthRepo) RecordFailure(clientID int, threshold int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO client_health (client_id, healthy, last_probe_at, consecutive_failures)
+		VALUES (?, CASE WHEN 1 >= ? THEN 0 ELSE 1 END, ?, 1)
+		ON CONFLICT(client_id) DO UPDATE SET
+			consecutive_failures = consecutive_failures + 1,
+			last_probe_at = excluded.last_probe_at,
+			healthy = CASE WHEN consecutive_failures + 1 >= ? THEN 0 ELSE healthy END`,
+		clientID, threshold, time.Now(), threshold)
+	if err != nil {
+		return fmt.Errorf("record health failure: %w", err)
+	}
+	return nil
+}
+
+// RecordSuccess marks clientID healthy and resets its consecutive failure count.
+func (r *HealthRepo) RecordSuccess(clientID int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO client_health (client_id, healthy, last_probe_at, consecutive_failures)
+		VALUES (?, 1, ?, 0)
+		ON CONFLICT(client_id) DO UPDATE SET
+			healthy = 1,
+			consecutive_failures = 0,
+			last_probe_at = excluded.last_probe_at`,
+		clientID, time.Now())
+	if err != nil {
+		return fmt.Errorf("record health success: %w", err)
+	}
+	return nil
+}
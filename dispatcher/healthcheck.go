@@ -0,0 +1,83 @@
+package dispatcher
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Target identifies a client's webhook endpoint for health probing.
+type Target struct {
+	ClientID int
+	Endpoint string
+}
+
+// HealthChecker periodically probes unhealthy clients with a HEAD request and restores
+// them to healthy once a probe succeeds, mirroring a healthcheck-enabled connection pool.
+type HealthChecker struct {
+	health  *HealthRepo
+	timeout time.Duration
+	targets func() ([]Target, error)
+}
+
+// NewHealthChecker returns a HealthChecker that probes the endpoints targets returns,
+// using timeout for each probe request.
+func NewHealthChecker(health *HealthRepo, timeout time.Duration, targets func() ([]Target, error)) *HealthChecker {
+	return &HealthChecker{health: health, timeout: timeout, targets: targets}
+}
+
+// Start runs the probe loop in the background every interval until ctx is canceled.
+func (c *HealthChecker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.probeUnhealthy()
+			}
+		}
+	}()
+}
+
+func (c *HealthChecker) probeUnhealthy() {
+	targets, err := c.targets()
+	if err != nil {
+		log.Println("healthcheck: list targets:", err)
+		return
+	}
+
+	for _, target := range targets {
+		healthy, err := c.health.IsHealthy(target.ClientID)
+		if err != nil {
+			log.Println("healthcheck: read health:", err)
+			continue
+		}
+		if healthy {
+			continue
+		}
+
+		c.probe(target)
+	}
+}
+
+func (c *HealthChecker) probe(target Target) {
+	client := &http.Client{Timeout: c.timeout}
+
+	resp, err := client.Head(target.Endpoint)
+	if err != nil || resp.StatusCode >= 500 {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return
+	}
+	resp.Body.Close()
+
+	if err := c.health.RecordSuccess(target.ClientID); err != nil {
+		log.Println("healthcheck: record success:", err)
+	}
+}
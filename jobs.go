@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/scheduler"
+)
+
+// jobRepo persists scheduled jobs; schedulerEngine fires them on their cron schedule.
+var jobRepo *scheduler.JobRepo
+var schedulerEngine scheduler.Engine
+
+// schedulerHandlers maps a Job.TargetEndpoint to the in-process action it triggers.
+var schedulerHandlers = map[string]scheduler.Handler{
+	"assignLead": func() error {
+		_, err := doAssignLead()
+		if errors.Is(err, errNoSuitableClient) {
+			return nil
+		}
+		return err
+	},
+}
+
+// setupScheduler wires a JobRepo and CronEngine to db and starts the engine so any jobs
+// already persisted in scheduled_jobs begin ticking.
+func setupScheduler(ctx context.Context) error {
+	jobRepo = scheduler.NewJobRepo(db)
+	schedulerEngine = scheduler.NewCronEngine(jobRepo, schedulerHandlers)
+	return schedulerEngine.Start(ctx)
+}
+
+// createNewJob processes a POST request to persist a new scheduled job.
+func createNewJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Name           string `json:"name"`
+		CronExpr       string `json:"cron_expr"`
+		TargetEndpoint string `json:"target_endpoint"`
+		Active         *bool  `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if body.Name == "" {
+		http.Error(w, "Job name is missing", http.StatusBadRequest)
+		return
+	}
+
+	// A job that doesn't say otherwise should start firing right away.
+	active := true
+	if body.Active != nil {
+		active = *body.Active
+	}
+
+	created, err := jobRepo.Create(scheduler.Job{
+		Name:           body.Name,
+		CronExpr:       body.CronExpr,
+		TargetEndpoint: body.TargetEndpoint,
+		Active:         active,
+	})
+	if err != nil {
+		http.Error(w, "Failed to create job", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	if err := schedulerEngine.AddJob(created); err != nil {
+		log.Printf("job %q persisted but failed to register with the running scheduler: %v", created.Name, err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// listJobs retrieves every scheduled job and sends them as a JSON response.
+func listJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobs, err := jobRepo.List()
+	if err != nil {
+		http.Error(w, "Failed to retrieve jobs", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// deleteJob removes the scheduled job identified by the "id" path parameter.
+func deleteJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := jobRepo.Delete(id); err != nil {
+		http.Error(w, "Failed to delete job", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	if err := schedulerEngine.RemoveJob(id); err != nil {
+		log.Printf("job %d deleted but failed to unregister from the running scheduler: %v", id, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
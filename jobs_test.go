@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/migrations/migrationtest"
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/scheduler"
+)
+
+// TestCreateNewJob is a table-driven test for the createNewJob handler. It checks that a
+// well-formed job is accepted with 201, and that a missing name is rejected with 400.
+func TestCreateNewJob(t *testing.T) {
+	db = migrationtest.NewTestDB(t, "./test-clients-create-job.db")
+	setupSession()
+	setupDispatcher(context.Background())
+	jobRepo = scheduler.NewJobRepo(db)
+	schedulerEngine = scheduler.NewDummyEngine(jobRepo, schedulerHandlers)
+
+	testCases := []struct {
+		Name       string
+		Body       scheduler.Job
+		WantStatus int
+	}{
+		{
+			Name:       "valid daily job",
+			Body:       scheduler.Job{Name: "daily-assign", CronExpr: "@daily", TargetEndpoint: "assignLead", Active: true},
+			WantStatus: http.StatusCreated,
+		},
+		{
+			Name:       "missing name",
+			Body:       scheduler.Job{CronExpr: "@daily", TargetEndpoint: "assignLead", Active: true},
+			WantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		jsonBody, err := json.Marshal(tc.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", apiPrefix+"jobs", bytes.NewBuffer(jsonBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		createNewJob(resp, req)
+
+		if resp.Code != tc.WantStatus {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", tc.Name, resp.Code, tc.WantStatus)
+		}
+	}
+}
+
+// TestCreateNewJobDefaultsActiveTrue verifies that a job posted without an "active" field
+// is created active, so it starts firing on its schedule instead of being silently skipped
+// by the engine until someone notices and flips it on.
+func TestCreateNewJobDefaultsActiveTrue(t *testing.T) {
+	db = migrationtest.NewTestDB(t, "./test-clients-create-job-default-active.db")
+	setupSession()
+	setupDispatcher(context.Background())
+	jobRepo = scheduler.NewJobRepo(db)
+	schedulerEngine = scheduler.NewDummyEngine(jobRepo, schedulerHandlers)
+
+	body := []byte(`{"name": "no-active-field", "cron_expr": "@daily", "target_endpoint": "assignLead"}`)
+
+	req, err := http.NewRequest("POST", apiPrefix+"jobs", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	createNewJob(resp, req)
+
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v", resp.Code, http.StatusCreated)
+	}
+
+	var created scheduler.Job
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	if !created.Active {
+		t.Fatal(`expected a job posted without an "active" field to default to active`)
+	}
+}
+
+// TestDummyEngineFire verifies that firing a job wired to the "assignLead" target endpoint
+// increments the selected client's ExistingLeads exactly once per tick.
+func TestDummyEngineFire(t *testing.T) {
+	db = migrationtest.NewTestDB(t, "./test-clients-fire-job.db")
+	setupSession()
+	setupDispatcher(context.Background())
+	jobRepo = scheduler.NewJobRepo(db)
+	engine := scheduler.NewDummyEngine(jobRepo, schedulerHandlers)
+
+	client := Client{Name: "Scheduled Client", WorkingHours: "00:00-23:59", Priority: 1, LeadCapacity: 5, ExistingLeads: 0}
+	jsonClient, err := json.Marshal(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("POST", apiPrefix+"clients", bytes.NewBuffer(jsonClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+	createNewClient(resp, req)
+
+	var created map[string]string
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := jobRepo.Create(scheduler.Job{Name: "tick-assign", CronExpr: "@every 1m", TargetEndpoint: "assignLead", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.Fire(job.ID); err != nil {
+		t.Fatalf("Fire returned unexpected error: %v", err)
+	}
+
+	row := db.QueryRow("SELECT existing_leads FROM clients WHERE uuid = ?", created["id"])
+	var existingLeads int
+	if err := row.Scan(&existingLeads); err != nil {
+		t.Fatal(err)
+	}
+
+	if existingLeads != 1 {
+		t.Fatalf("existing_leads after one tick, got: %v, want: %v", existingLeads, 1)
+	}
+}
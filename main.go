@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -10,22 +11,42 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/migrations"
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/session"
 )
 
+// apiPrefix is prepended to every versioned route the CRM exposes.
+const apiPrefix = "/api/v1/"
+
 // Global DB connection
 var db *sql.DB
 
+// nowFunc returns the current time and is swapped out in tests to drive a fake clock.
+var nowFunc = time.Now
+
 func main() {
 	_, err := setupDatabase()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	setupSession()
+
+	ctx := context.Background()
+
+	if err := setupScheduler(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	setupDispatcher(ctx)
+
 	startServer()
 }
 
-// Setting up SQLite DB and create Clients table for the first run
+// Setting up SQLite DB and migrating it to the latest schema for the first run
 func setupDatabase() (*sql.DB, error) {
 	var err error
 
@@ -34,27 +55,61 @@ func setupDatabase() (*sql.DB, error) {
 		log.Fatal(err)
 	}
 
-	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS clients (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, working_hours TEXT, priority INTEGER, lead_capacity INTEGER, existing_leads INTEGER)`)
+	appliedVersions, err := migrations.MigrateToLatest(db)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if len(appliedVersions) > 0 {
+		log.Printf("applied migrations: %v", appliedVersions)
+	}
 
 	return db, nil
 }
 
 // Starting up local server and handle the routes
 func startServer() {
-	http.HandleFunc("/clients/new", createNewClient)
-	http.HandleFunc("/clients", retrieveAllClients)
-	http.HandleFunc("/client", retrieveClient)
-	http.HandleFunc("/assignLead", assignLead)
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST "+apiPrefix+"clients", createNewClient)
+	mux.HandleFunc("GET "+apiPrefix+"clients", retrieveAllClients)
+	mux.HandleFunc("GET "+apiPrefix+"clients/{id}", retrieveClient)
+	mux.HandleFunc("GET "+apiPrefix+"clients/{id}/health", retrieveClientHealth)
+	mux.Handle("GET "+apiPrefix+"leads", session.Authenticate(sessionRepo)(http.HandlerFunc(assignLead)))
+	mux.HandleFunc("POST "+apiPrefix+"jobs", createNewJob)
+	mux.HandleFunc("GET "+apiPrefix+"jobs", listJobs)
+	mux.HandleFunc("DELETE "+apiPrefix+"jobs/{id}", deleteJob)
+
+	// Deprecated pre-v1 routes, kept as permanent redirects for old callers.
+	mux.HandleFunc("/clients/new", deprecatedRedirect(apiPrefix+"clients"))
+	mux.HandleFunc("/clients", deprecatedRedirect(apiPrefix+"clients"))
+	mux.HandleFunc("/client", deprecatedClientRedirect)
+	mux.HandleFunc("/assignLead", deprecatedRedirect(apiPrefix+"leads"))
+
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}
+
+// deprecatedRedirect returns a handler that permanently redirects to target,
+// preserving the original request's method and query string.
+func deprecatedRedirect(target string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dest := target
+		if r.URL.RawQuery != "" {
+			dest += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, dest, http.StatusPermanentRedirect)
+	}
+}
 
-	log.Fatal(http.ListenAndServe(":8080", nil))
+// deprecatedClientRedirect rewrites the legacy "/client?id=..." lookup into
+// the versioned "/api/v1/clients/{id}" path.
+func deprecatedClientRedirect(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	http.Redirect(w, r, apiPrefix+"clients/"+id, http.StatusPermanentRedirect)
 }
 
 // createNewClient processes a POST request to create a new client.
-// It checks if the client already exists in the database, and if so, returns the existing client's ID. Otherwise, it inserts the new client
-// into the database and returns the newly generated ID.
+// It checks if the client already exists in the database, and if so, returns the existing client's UUID. Otherwise, it allocates a new
+// UUIDv4, inserts the new client into the database, and returns it.
 func createNewClient(w http.ResponseWriter, r *http.Request) {
 	var client Client
 
@@ -69,31 +124,42 @@ func createNewClient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	row := db.QueryRow("SELECT id FROM clients WHERE name = ?", client.Name)
-	var existingID int
-	err = row.Scan(&existingID)
+	row := db.QueryRow("SELECT uuid FROM clients WHERE name = ?", client.Name)
+	var existingUUID string
+	err = row.Scan(&existingUUID)
 
 	if !errors.Is(err, sql.ErrNoRows) {
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]int{
-			"id": existingID,
+		json.NewEncoder(w).Encode(map[string]string{
+			"id": existingUUID,
 		})
 		return
 	}
 
-	res, err := db.Exec("INSERT INTO clients (name, working_hours, priority, lead_capacity, existing_leads) VALUES (?, ?, ?, ?, ?)",
-		client.Name, client.WorkingHours, client.Priority, client.LeadCapacity, client.ExistingLeads)
+	clientUUID := uuid.New().String()
+
+	res, err := db.Exec("INSERT INTO clients (uuid, name, working_hours, priority, lead_capacity, existing_leads, webhook_url) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		clientUUID, client.Name, client.WorkingHours, client.Priority, client.LeadCapacity, client.ExistingLeads, client.WebhookURL)
 	if err != nil {
 		log.Fatal(err)
 	}
-	id, err := res.LastInsertId()
+
+	internalID, err := res.LastInsertId()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	token, err := mintSessionToken(int(internalID))
+	if err != nil {
+		http.Error(w, "Failed to mint session token", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]int64{
-		"id": id,
+	json.NewEncoder(w).Encode(map[string]string{
+		"id":    clientUUID,
+		"token": token,
 	})
 }
 
@@ -108,7 +174,7 @@ func retrieveAllClients(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.Query("SELECT id, name, working_hours, priority, lead_capacity, existing_leads FROM clients")
+	rows, err := db.Query("SELECT id, uuid, name, working_hours, priority, lead_capacity, existing_leads, webhook_url FROM clients")
 	if err != nil {
 		http.Error(w, "Failed to execute query", http.StatusInternalServerError)
 		log.Println(err)
@@ -119,7 +185,7 @@ func retrieveAllClients(w http.ResponseWriter, r *http.Request) {
 	var clients []Client
 	for rows.Next() {
 		var c Client
-		err := rows.Scan(&c.ID, &c.Name, &c.WorkingHours, &c.Priority, &c.LeadCapacity, &c.ExistingLeads)
+		err := rows.Scan(&c.ID, &c.UUID, &c.Name, &c.WorkingHours, &c.Priority, &c.LeadCapacity, &c.ExistingLeads, &c.WebhookURL)
 		if err != nil {
 			http.Error(w, "Failed to scan row", http.StatusInternalServerError)
 			log.Println(err)
@@ -140,7 +206,7 @@ func retrieveAllClients(w http.ResponseWriter, r *http.Request) {
 }
 
 // retrieveClient handles a GET request to retrieve the details of a specific client.
-// It verifies that the request method is GET and checks for the presence of the "id" query parameter.
+// It verifies that the request method is GET and checks for the presence of the "id" path parameter.
 // If the client is found in the database, its details are extracted and returned as a JSON response.
 // If the client is not found, an appropriate error response is sent.
 // If any error occurs during the process, an internal server error is returned with an error log.
@@ -150,15 +216,15 @@ func retrieveClient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := r.URL.Query().Get("id")
+	id := r.PathValue("id")
 	if id == "" {
 		http.Error(w, "Client ID is missing", http.StatusBadRequest)
 		return
 	}
 
-	row := db.QueryRow("SELECT * FROM clients WHERE id = ?", id)
+	row := db.QueryRow("SELECT id, uuid, name, working_hours, priority, lead_capacity, existing_leads, webhook_url FROM clients WHERE uuid = ?", id)
 	var c Client
-	err := row.Scan(&c.ID, &c.Name, &c.WorkingHours, &c.Priority, &c.LeadCapacity, &c.ExistingLeads)
+	err := row.Scan(&c.ID, &c.UUID, &c.Name, &c.WorkingHours, &c.Priority, &c.LeadCapacity, &c.ExistingLeads, &c.WebhookURL)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			http.Error(w, "Client not found", http.StatusNotFound)
@@ -173,71 +239,123 @@ func retrieveClient(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(c)
 }
 
-// assignLead retrieves the list of clients from the database, sorts them based on priority and existing leads,
-// and assigns a lead to the first suitable client within their working hours. If a client is found and has available
-// lead capacity, the function updates the client's existing_leads counter and returns the client ID as a JSON response.
-// If no suitable client is found, it returns a "No suitable client found" error response.
-func assignLead(w http.ResponseWriter, r *http.Request) {
-	currentTime := time.Now()
+// errNoSuitableClient is returned by doAssignLead when no client is currently within its
+// working hours with spare lead capacity.
+var errNoSuitableClient = errors.New("no suitable client found")
 
-	rows, err := db.Query("SELECT id, priority, existing_leads, working_hours, lead_capacity FROM clients ORDER BY priority DESC, existing_leads ASC")
+// assignLead handles a request to assign a lead by delegating to doAssignLead and
+// translating its outcome into an HTTP response.
+func assignLead(w http.ResponseWriter, r *http.Request) {
+	clientUUID, err := doAssignLead()
 	if err != nil {
-		http.Error(w, "Failed to retrieve clients", http.StatusInternalServerError)
-		log.Println(err)
+		if errors.Is(err, errNoSuitableClient) {
+			http.Error(w, "No suitable client found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to assign lead", http.StatusInternalServerError)
+			log.Println(err)
+		}
 		return
 	}
 
+	w.WriteHeader(http.StatusAccepted)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"client_id": clientUUID,
+	})
+}
+
+// doAssignLead retrieves the list of clients from the database, sorts them based on priority and existing leads,
+// and assigns a lead to the first suitable client within their working hours. If a client is found and has available
+// lead capacity, it enqueues an asynchronous webhook delivery (so a slow or dead client
+// webhook can't block the caller) and updates the client's existing_leads counter, then
+// returns its UUID. If no suitable client is found, it returns errNoSuitableClient.
+func doAssignLead() (string, error) {
+	currentTime := nowFunc()
+
+	rows, err := db.Query("SELECT id, uuid, priority, existing_leads, working_hours, lead_capacity, webhook_url FROM clients ORDER BY priority DESC, existing_leads ASC")
+	if err != nil {
+		return "", fmt.Errorf("retrieve clients: %w", err)
+	}
+
 	clients := make([]Client, 0)
 	for rows.Next() {
 		var client Client
-		err := rows.Scan(&client.ID, &client.Priority, &client.ExistingLeads, &client.WorkingHours, &client.LeadCapacity)
+		err := rows.Scan(&client.ID, &client.UUID, &client.Priority, &client.ExistingLeads, &client.WorkingHours, &client.LeadCapacity, &client.WebhookURL)
 		if err != nil {
-			http.Error(w, "Failed to retrieve client data", http.StatusInternalServerError)
-			log.Println(err)
-			return
+			rows.Close()
+			return "", fmt.Errorf("retrieve client data: %w", err)
 		}
 		clients = append(clients, client)
 	}
 	rows.Close()
 
 	for _, client := range clients {
+		healthy, err := healthRepo.IsHealthy(client.ID)
+		if err != nil {
+			return "", fmt.Errorf("check client health: %w", err)
+		}
+		if !healthy {
+			continue
+		}
+
 		workingHours, err := parseTimePeriod(client.WorkingHours)
 		if err != nil {
-			http.Error(w, "Failed to parse working hours", http.StatusInternalServerError)
-			log.Println(err)
-			return
+			return "", fmt.Errorf("parse working hours: %w", err)
 		}
 
-		if currentTime.After(workingHours.Start) && currentTime.Before(workingHours.End) {
-			if client.ExistingLeads < client.LeadCapacity {
-				_, err = db.Exec("UPDATE clients SET existing_leads = existing_leads + 1 WHERE id = ?", client.ID)
-				if err != nil {
-					http.Error(w, "Failed to update client's lead counter", http.StatusInternalServerError)
-					log.Println(err)
-					return
+		withinWorkingHours := currentTime.After(workingHours.Start) && currentTime.Before(workingHours.End)
+		if workingHours.End.Before(workingHours.Start) {
+			// The period crosses midnight, e.g. "16:30-00:30".
+			withinWorkingHours = currentTime.After(workingHours.Start) || currentTime.Before(workingHours.End)
+		}
+
+		if !withinWorkingHours || client.ExistingLeads >= client.LeadCapacity {
+			continue
+		}
+
+		if client.WebhookURL != "" {
+			clientID, webhookURL, clientUUID := client.ID, client.WebhookURL, client.UUID
+			go func() {
+				if err := webhookDispatcher.Dispatch(clientID, webhookURL, map[string]string{"client_id": clientUUID}); err != nil {
+					log.Println("dispatch lead webhook:", err)
 				}
+			}()
+		}
 
-				w.WriteHeader(http.StatusAccepted)
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(map[string]int{
-					"client_id": client.ID,
-				})
-			} else {
-				continue
-			}
-			return
+		if _, err := db.Exec("UPDATE clients SET existing_leads = existing_leads + 1 WHERE id = ?", client.ID); err != nil {
+			return "", fmt.Errorf("update client's lead counter: %w", err)
 		}
+
+		return client.UUID, nil
 	}
 
-	http.Error(w, "No suitable client found", http.StatusNotFound)
+	return "", errNoSuitableClient
 }
 
-// parseTimePeriod parses a string representation of a time period in the format "start-end" and returns
-// a TimePeriod struct with the parsed start and end times. It also validates the format and checks for errors.
-// The start and end times are set to the current year, month, and day.
+// parseTimePeriod parses a string representation of a time period in the format "start-end" or
+// "start-end IANA/Zone" (e.g. "09:00-17:00" or "09:00-17:00 Europe/Berlin") and returns a TimePeriod
+// struct with the parsed start and end times anchored on today's date in that timezone. It also
+// validates the format and checks for errors. A period whose end precedes its start crosses midnight;
+// callers are expected to handle that case rather than treating it as an error.
 func parseTimePeriod(s string) (TimePeriod, error) {
 	var period TimePeriod
-	times := strings.Split(s, "-")
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return period, errors.New("invalid time period format")
+	}
+
+	loc := time.Local
+	if len(fields) == 2 {
+		var err error
+		loc, err = time.LoadLocation(fields[1])
+		if err != nil {
+			return period, fmt.Errorf("invalid timezone: %v", err)
+		}
+		period.IANA = fields[1]
+	}
+
+	times := strings.Split(fields[0], "-")
 	if len(times) != 2 {
 		return period, errors.New("invalid time period format")
 	}
@@ -252,14 +370,10 @@ func parseTimePeriod(s string) (TimePeriod, error) {
 		return period, fmt.Errorf("invalid end time: %v", err)
 	}
 
-	now := time.Now()
-	year, month, day := now.Date()
-
-	start = time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, start.Location())
-	end = time.Date(year, month, day, end.Hour(), end.Minute(), 0, 0, end.Location())
+	year, month, day := nowFunc().In(loc).Date()
 
-	period.Start = start
-	period.End = end
+	period.Start = time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, loc)
+	period.End = time.Date(year, month, day, end.Hour(), end.Minute(), 0, 0, loc)
 
 	return period, nil
 }
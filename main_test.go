@@ -2,14 +2,15 @@ package main
 
 import (
 	"bytes"
-	"database/sql"
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 	"time"
+
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/migrations/migrationtest"
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/session"
 )
 
 // clientsTest is a variable of type []Client used for testing purposes.
@@ -20,36 +21,19 @@ var clientsTest = [...]Client{
 	{Name: "Client Three", WorkingHours: "16:30-00:30", Priority: 2, LeadCapacity: 7, ExistingLeads: 0},
 }
 
-// TestCreateNewClient is a unit test function that tests the createNewClient and retrieveClient handlers.
-// It sets up a test database connection and starts a local server. It then sends multiple POST requests to create new clients,
-// verifies the HTTP status codes and the response payloads, and uses the client ID from the response to fetch the client details.
-// It checks if the retrieved client details match the expected values.
-// The function utilizes the clientsTest variable for testing purposes, which is an array of Client struct instances with different properties.
-// This test function is designed to be used with the Go testing package and should be executed using the "go test" command.
-func TestCreateNewClient(t *testing.T) {
-	var err error
-	db, err = sql.Open("sqlite3", "./test-clients.db")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer db.Close()
-
-	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS clients (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, working_hours TEXT, priority INTEGER, lead_capacity INTEGER, existing_leads INTEGER)`)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	go startServer()
-
-	time.Sleep(1 * time.Second)
+// seedClients inserts clientsTest into db via the createNewClient handler and
+// returns the assigned client UUIDs in the same order.
+func seedClients(t *testing.T) []string {
+	t.Helper()
 
+	ids := make([]string, 0, len(clientsTest))
 	for _, client := range clientsTest {
 		jsonClient, err := json.Marshal(client)
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		req, err := http.NewRequest("POST", "/clients/new", bytes.NewBuffer(jsonClient))
+		req, err := http.NewRequest("POST", apiPrefix+"clients", bytes.NewBuffer(jsonClient))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -58,23 +42,42 @@ func TestCreateNewClient(t *testing.T) {
 		createNewClient(resp, req)
 
 		if status := resp.Code; status != http.StatusCreated {
-			t.Errorf("handler returned wrong status code: got %v want %v",
+			t.Fatalf("handler returned wrong status code: got %v want %v",
 				status, http.StatusCreated)
 		}
 
-		var response map[string]int
-		err = json.Unmarshal(resp.Body.Bytes(), &response)
-		if err != nil {
+		var response map[string]string
+		if err := json.Unmarshal(resp.Body.Bytes(), &response); err != nil {
 			t.Fatal(err)
 		}
 
-		// Using the client ID from the http response, fetch the client details
-		req, err = http.NewRequest("GET", "/client?id="+fmt.Sprint(response["id"]), nil)
+		ids = append(ids, response["id"])
+	}
+
+	return ids
+}
+
+// TestCreateNewClient is a unit test function that tests the createNewClient and retrieveClient handlers.
+// It migrates a fresh database, then sends multiple POST requests to create new clients,
+// verifies the HTTP status codes and the response payloads, and uses the client ID from the response to fetch the client details.
+// It checks if the retrieved client details match the expected values.
+// The function utilizes the clientsTest variable for testing purposes, which is an array of Client struct instances with different properties.
+// This test function is designed to be used with the Go testing package and should be executed using the "go test" command.
+func TestCreateNewClient(t *testing.T) {
+	db = migrationtest.NewTestDB(t, "./test-clients-create.db")
+	setupSession()
+	setupDispatcher(context.Background())
+
+	ids := seedClients(t)
+
+	for i, client := range clientsTest {
+		req, err := http.NewRequest("GET", apiPrefix+"clients/"+ids[i], nil)
 		if err != nil {
 			t.Fatal(err)
 		}
+		req.SetPathValue("id", ids[i])
 
-		resp = httptest.NewRecorder()
+		resp := httptest.NewRecorder()
 		retrieveClient(resp, req)
 
 		if status := resp.Code; status != http.StatusOK {
@@ -83,13 +86,13 @@ func TestCreateNewClient(t *testing.T) {
 		}
 
 		var clientTest Client
-		err = json.Unmarshal(resp.Body.Bytes(), &clientTest)
-		if err != nil {
+		if err := json.Unmarshal(resp.Body.Bytes(), &clientTest); err != nil {
 			t.Fatal(err)
 		}
 
-		// Check the client details match what we expect
-		if clientTest.Name != client.Name ||
+		// Check the client details match what we expect, round-tripping the UUID
+		if clientTest.UUID != ids[i] ||
+			clientTest.Name != client.Name ||
 			clientTest.WorkingHours != client.WorkingHours ||
 			clientTest.Priority != client.Priority ||
 			clientTest.LeadCapacity != client.LeadCapacity ||
@@ -99,87 +102,216 @@ func TestCreateNewClient(t *testing.T) {
 	}
 }
 
-// TestRetrieveAllClients is a unit test function that tests the retrieval of all clients from the server.
-// It sets up a test database connection and sends a GET request to retrieve all clients.
-// It verifies the HTTP status code and decodes the response into a slice of Client structs.
-// It then checks if the number of returned clients matches the expected value of 3.
-// The function utilizes the db and Client variables, which are declared globally.
+// TestRetrieveAllClients is a unit test function that tests the retrieval of all clients.
+// It migrates a fresh database, seeds it with clientsTest, and sends a GET request to
+// retrieve all clients. It verifies the HTTP status code and decodes the response into a
+// slice of Client structs, then checks if the number of returned clients matches the
+// number seeded.
 // This test function is designed to be used with the Go testing package and should be executed using the "go test" command.
 func TestRetrieveAllClients(t *testing.T) {
-	var err error
-	db, err = sql.Open("sqlite3", "./test-clients.db")
+	db = migrationtest.NewTestDB(t, "./test-clients-retrieve-all.db")
+	setupSession()
+	setupDispatcher(context.Background())
+
+	seedClients(t)
+
+	req, err := http.NewRequest("GET", "/clients", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer db.Close()
 
-	resp, err := http.Get("http://localhost:8080/clients")
-	if err != nil {
-		t.Fatal(err)
+	resp := httptest.NewRecorder()
+	retrieveAllClients(resp, req)
+
+	if status := resp.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
-	defer resp.Body.Close()
 
 	var clients []Client
-
-	err = json.NewDecoder(resp.Body).Decode(&clients)
-	if err != nil {
+	if err := json.Unmarshal(resp.Body.Bytes(), &clients); err != nil {
 		t.Fatal(err)
 	}
 
-	if len(clients) != 3 {
-		t.Errorf("Expected only 3 clients in the response, but received %d", len(clients))
+	if len(clients) != len(clientsTest) {
+		t.Errorf("Expected only %d clients in the response, but received %d", len(clientsTest), len(clients))
 	}
 }
 
 // TestAssignLead is a unit test function that tests the assignLead handler.
-// It sets up a test database connection.
-// It sends a GET request to the /assignLead endpoint and verifies the HTTP status code.
-// It also checks if the response body contains the correct client ID.
-// The client ID is expected to be 2.
-// The test function utilizes the global db variable, which is a database connection.
-// The test database file is created at "./test-clients.db" and is removed after the test.
+// It migrates a fresh database, seeds it with clientsTest, pins the clock to 13:00 and sends
+// a GET request to the /api/v1/leads endpoint. It verifies the HTTP status code and that the
+// response body contains the ID of the highest-priority client with spare lead capacity during
+// its working hours (Client Two, the second client seeded).
 // This test function is designed to be used with the Go testing package and should be executed using the "go test" command.
 func TestAssignLead(t *testing.T) {
-	var err error
-	db, err = sql.Open("sqlite3", "./test-clients.db")
-	if err != nil {
-		t.Fatal(err)
+	db = migrationtest.NewTestDB(t, "./test-clients-assign-lead.db")
+	setupSession()
+	setupDispatcher(context.Background())
+
+	ids := seedClients(t)
+
+	originalNow := nowFunc
+	defer func() { nowFunc = originalNow }()
+	nowFunc = func() time.Time {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 13, 0, 0, 0, time.Local)
 	}
-	defer os.Remove("./test-clients.db")
-	defer db.Close()
 
-	resp, err := http.Get("http://localhost:8080/assignLead")
+	req, err := http.NewRequest("GET", apiPrefix+"leads", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusAccepted {
-		t.Fatalf("Expected status 202, got: %v", resp.StatusCode)
+	resp := httptest.NewRecorder()
+	assignLead(resp, req)
+
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got: %v", resp.Code)
 	}
 
 	respBody := struct {
-		ClientID int `json:"client_id"`
+		ClientID string `json:"client_id"`
 	}{}
 
-	err = json.NewDecoder(resp.Body).Decode(&respBody)
-	if err != nil {
+	if err := json.Unmarshal(resp.Body.Bytes(), &respBody); err != nil {
 		t.Fatal(err)
 	}
 
-	expectedID := 2
+	expectedID := ids[1]
 
 	if respBody.ClientID != expectedID {
 		t.Fatalf("Client ID mismatch, got: %v, want: %v", respBody.ClientID, expectedID)
 	}
 }
 
+// TestAssignLead_WebhookDispatchIsAsync verifies that assignLead returns immediately even
+// when the matched client's webhook is slow to respond, and that the lead still counts
+// against the client's capacity rather than waiting on (and potentially being lost to) the
+// delivery attempt.
+func TestAssignLead_WebhookDispatchIsAsync(t *testing.T) {
+	db = migrationtest.NewTestDB(t, "./test-clients-assign-lead-async-webhook.db")
+	setupSession()
+	setupDispatcher(context.Background())
+
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer func() {
+		close(blocked)
+		server.Close()
+	}()
+
+	client := Client{Name: "Webhook Client", WorkingHours: "00:00-23:59", Priority: 1, LeadCapacity: 5, ExistingLeads: 0, WebhookURL: server.URL}
+	jsonClient, err := json.Marshal(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createReq, err := http.NewRequest("POST", apiPrefix+"clients", bytes.NewBuffer(jsonClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createResp := httptest.NewRecorder()
+	createNewClient(createResp, createReq)
+
+	var created map[string]string
+	if err := json.Unmarshal(createResp.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	leadReq, err := http.NewRequest("GET", apiPrefix+"leads", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leadResp := httptest.NewRecorder()
+
+	start := time.Now()
+	assignLead(leadResp, leadReq)
+	elapsed := time.Since(start)
+
+	if leadResp.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got: %v", leadResp.Code)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("assignLead blocked on webhook delivery for %v, want it to return immediately", elapsed)
+	}
+
+	row := db.QueryRow("SELECT existing_leads FROM clients WHERE uuid = ?", created["id"])
+	var existingLeads int
+	if err := row.Scan(&existingLeads); err != nil {
+		t.Fatal(err)
+	}
+	if existingLeads != 1 {
+		t.Fatalf("existing_leads after assignment, got: %v, want: %v", existingLeads, 1)
+	}
+}
+
+// TestAuthenticateLeadsEndpoint drives /api/v1/leads through the session.Authenticate
+// middleware as it's wired in startServer: a missing token is rejected, a valid token
+// succeeds, and that same token keeps succeeding on a second call since the leads endpoint
+// is meant to be hit repeatedly rather than exchanged once.
+func TestAuthenticateLeadsEndpoint(t *testing.T) {
+	db = migrationtest.NewTestDB(t, "./test-clients-authenticate-leads.db")
+	setupSession()
+	setupDispatcher(context.Background())
+
+	seedClients(t)
+
+	// Pin the clock inside Client Two's working hours so a valid token always finds a client.
+	originalNow := nowFunc
+	defer func() { nowFunc = originalNow }()
+	nowFunc = func() time.Time {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 13, 0, 0, 0, time.Local)
+	}
+
+	handler := session.Authenticate(sessionRepo)(http.HandlerFunc(assignLead))
+
+	req, err := http.NewRequest("GET", apiPrefix+"leads", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusUnauthorized {
+		t.Fatalf("request without a token: got status %v, want %v", resp.Code, http.StatusUnauthorized)
+	}
+
+	token, err := mintSessionToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", apiPrefix+"leads", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusAccepted {
+			t.Fatalf("request %d with valid token: got status %v, want %v", i+1, resp.Code, http.StatusAccepted)
+		}
+	}
+}
+
 // TestParseTimePeriod is a unit test function that tests the parseTimePeriod function.
 // It defines multiple test cases with different inputs and expected outputs.
 // For each test case, it calls parseTimePeriod and verifies the result and error status.
 // If the test case is not expected to return an error, it also checks if the parsed TimePeriod matches the expected TimePeriod.
 // This test function is designed to be used with the Go testing package and should be executed using the "go test" command.
 func TestParseTimePeriod(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	testCases := []struct {
 		Input    string
 		Expected TimePeriod
@@ -198,6 +330,30 @@ func TestParseTimePeriod(t *testing.T) {
 			Expected: TimePeriod{},
 			IsError:  true,
 		},
+		{
+			// Crosses midnight: End ends up before Start, which assignLead must treat specially.
+			Input: "16:30-00:30",
+			Expected: TimePeriod{
+				Start: time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 16, 30, 0, 0, time.Local),
+				End:   time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 30, 0, 0, time.Local),
+			},
+			IsError: false,
+		},
+		{
+			// Timezone-aware: the "IANA/Zone" suffix is parsed with time.LoadLocation.
+			Input: "09:00-17:00 Europe/Berlin",
+			Expected: TimePeriod{
+				Start: time.Date(time.Now().In(berlin).Year(), time.Now().In(berlin).Month(), time.Now().In(berlin).Day(), 9, 0, 0, 0, berlin),
+				End:   time.Date(time.Now().In(berlin).Year(), time.Now().In(berlin).Month(), time.Now().In(berlin).Day(), 17, 0, 0, 0, berlin),
+				IANA:  "Europe/Berlin",
+			},
+			IsError: false,
+		},
+		{
+			Input:    "09:00-17:00 Not/AZone",
+			Expected: TimePeriod{},
+			IsError:  true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -211,12 +367,68 @@ func TestParseTimePeriod(t *testing.T) {
 			if !(res.Start.Hour() == tc.Expected.Start.Hour() &&
 				res.Start.Minute() == tc.Expected.Start.Minute() &&
 				res.End.Hour() == tc.Expected.End.Hour() &&
-				res.End.Minute() == tc.Expected.End.Minute()) {
-				t.Fatalf("TimePeriod mismatch, got: %v - %v, want: %v - %v for input: %v",
-					res.Start.Format("15:04"), res.End.Format("15:04"),
-					tc.Expected.Start.Format("15:04"), tc.Expected.End.Format("15:04"),
+				res.End.Minute() == tc.Expected.End.Minute() &&
+				res.IANA == tc.Expected.IANA) {
+				t.Fatalf("TimePeriod mismatch, got: %v - %v (%v), want: %v - %v (%v) for input: %v",
+					res.Start.Format("15:04"), res.End.Format("15:04"), res.IANA,
+					tc.Expected.Start.Format("15:04"), tc.Expected.End.Format("15:04"), tc.Expected.IANA,
 					tc.Input)
 			}
 		}
 	}
 }
+
+// TestAssignLead_Overnight verifies that assignLead matches a client whose working hours cross
+// midnight (e.g. "16:30-00:30") when the current time is after Start but before midnight.
+func TestAssignLead_Overnight(t *testing.T) {
+	db = migrationtest.NewTestDB(t, "./test-clients-assign-lead-overnight.db")
+	setupSession()
+	setupDispatcher(context.Background())
+
+	overnightClient := Client{Name: "Overnight Client", WorkingHours: "16:30-00:30", Priority: 1, LeadCapacity: 5, ExistingLeads: 0}
+	jsonClient, err := json.Marshal(overnightClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("POST", apiPrefix+"clients", bytes.NewBuffer(jsonClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+	createNewClient(resp, req)
+
+	var created map[string]string
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	originalNow := nowFunc
+	defer func() { nowFunc = originalNow }()
+	nowFunc = func() time.Time {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 23, 0, 0, 0, time.Local)
+	}
+
+	req, err = http.NewRequest("GET", apiPrefix+"leads", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp = httptest.NewRecorder()
+	assignLead(resp, req)
+
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got: %v", resp.Code)
+	}
+
+	respBody := struct {
+		ClientID string `json:"client_id"`
+	}{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &respBody); err != nil {
+		t.Fatal(err)
+	}
+
+	if respBody.ClientID != created["id"] {
+		t.Fatalf("Client ID mismatch, got: %v, want: %v", respBody.ClientID, created["id"])
+	}
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import "database/sql"
+
+// migration001CreateClients ports the original ad-hoc "CREATE TABLE IF NOT
+// EXISTS clients" statement into the first tracked migration.
+var migration001CreateClients = Migration{
+	Version: 1,
+	Up: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS clients (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			working_hours TEXT,
+			priority INTEGER,
+			lead_capacity INTEGER,
+			existing_leads INTEGER
+		)`)
+		return err
+	},
+	Down: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE IF EXISTS clients`)
+		return err
+	},
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import "database/sql"
+
+// migration002AddClientUUID adds the UUID column clients are now addressed by
+// over the API, while leaving the autoincrement id column in place as the
+// internal primary key.
+var migration002AddClientUUID = Migration{
+	Version: 2,
+	Up: func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`ALTER TABLE clients ADD COLUMN uuid TEXT`); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_clients_uuid ON clients (uuid)`)
+		return err
+	},
+	Down: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP INDEX IF EXISTS idx_clients_uuid`)
+		return err
+	},
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import "database/sql"
+
+// migration003CreateScheduledJobs adds the table backing the scheduler
+// subsystem's persisted cron jobs.
+var migration003CreateScheduledJobs = Migration{
+	Version: 3,
+	Up: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS scheduled_jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			cron_expr TEXT NOT NULL,
+			target_endpoint TEXT NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT 1,
+			last_run_at DATETIME,
+			last_status TEXT
+		)`)
+		return err
+	},
+	Down: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE IF EXISTS scheduled_jobs`)
+		return err
+	},
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import "database/sql"
+
+// migration004CreateSessionKeys adds the table backing the expiring session tokens minted
+// when a client is created.
+var migration004CreateSessionKeys = Migration{
+	Version: 4,
+	Up: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS session_keys (
+			key TEXT PRIMARY KEY,
+			client_id INTEGER NOT NULL,
+			expires_at DATETIME NOT NULL
+		)`)
+		return err
+	},
+	Down: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE IF EXISTS session_keys`)
+		return err
+	},
+}
@@ -0,0 +1,17 @@
+package migrations
+
+import "database/sql"
+
+// migration005AddClientWebhookURL adds the outbound URL assignLead notifies when it picks
+// a client, leaving it empty for clients that haven't configured one yet.
+var migration005AddClientWebhookURL = Migration{
+	Version: 5,
+	Up: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`ALTER TABLE clients ADD COLUMN webhook_url TEXT NOT NULL DEFAULT ''`)
+		return err
+	},
+	Down: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`ALTER TABLE clients DROP COLUMN webhook_url`)
+		return err
+	},
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import "database/sql"
+
+// migration006CreateClientHealth adds the table the dispatcher uses to track each client's
+// webhook reachability, so assignLead can skip clients that are currently unhealthy.
+var migration006CreateClientHealth = Migration{
+	Version: 6,
+	Up: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS client_health (
+			client_id INTEGER PRIMARY KEY,
+			healthy BOOLEAN NOT NULL DEFAULT 1,
+			last_probe_at DATETIME,
+			consecutive_failures INTEGER NOT NULL DEFAULT 0
+		)`)
+		return err
+	},
+	Down: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE IF EXISTS client_health`)
+		return err
+	},
+}
@@ -0,0 +1,104 @@
+// Package migrations manages the versioned SQLite schema for the CRM.
+// Applied versions are tracked in a schema_migrations table so MigrateToLatest
+// can be called idempotently on every startup.
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Migration represents a single reversible schema change, identified by a
+// monotonically increasing Version.
+type Migration struct {
+	Version int
+	Up      func(*sql.Tx) error
+	Down    func(*sql.Tx) error
+}
+
+// registered holds every migration the CRM knows about, in the order they
+// were introduced. New migrations are appended here as the schema evolves.
+var registered = []Migration{
+	migration001CreateClients,
+	migration002AddClientUUID,
+	migration003CreateScheduledJobs,
+	migration004CreateSessionKeys,
+	migration005AddClientWebhookURL,
+	migration006CreateClientHealth,
+}
+
+const createSchemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, applied_at DATETIME NOT NULL)`
+
+// MigrateToLatest applies every registered migration that hasn't already run
+// against db, in version order, and returns the versions it applied.
+func MigrateToLatest(db *sql.DB) (appliedVersions []int, err error) {
+	if _, err := db.Exec(createSchemaMigrationsTable); err != nil {
+		return nil, fmt.Errorf("create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedVersionSet(db)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := append([]Migration(nil), registered...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Version < ordered[j].Version })
+
+	for _, m := range ordered {
+		if applied[m.Version] {
+			continue
+		}
+
+		if err := applyMigration(db, m); err != nil {
+			return appliedVersions, err
+		}
+
+		appliedVersions = append(appliedVersions, m.Version)
+	}
+
+	return appliedVersions, nil
+}
+
+func appliedVersionSet(db *sql.DB) (map[int]bool, error) {
+	rows, err := db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("scan schema_migrations: %w", err)
+		}
+		applied[v] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func applyMigration(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", m.Version, err)
+	}
+
+	if err := m.Up(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("apply migration %d: %w", m.Version, err)
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)", m.Version, time.Now()); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %d: %w", m.Version, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// Package migrationtest provides test-only helpers for setting up a migrated database.
+// It lives outside package migrations so production code never links "testing" and its
+// -test.* flags by importing migrations.
+package migrationtest
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/migrations"
+)
+
+// NewTestDB drops any stale SQLite file at path, migrates a fresh one to the
+// latest schema, and registers cleanup so the database is closed and removed
+// once the test finishes. It lets each test run against its own schema
+// instead of sharing a long-lived file with whatever ran before it.
+func NewTestDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		t.Fatalf("remove stale test db %q: %v", path, err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("open test db %q: %v", path, err)
+	}
+
+	if _, err := migrations.MigrateToLatest(db); err != nil {
+		t.Fatalf("migrate test db %q: %v", path, err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(path)
+	})
+
+	return db
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// CronEngine loads active jobs from a JobRepo and fires their registered Handler via
+// robfig/cron/v3 on each tick of their CronExpr.
+type CronEngine struct {
+	repo     *JobRepo
+	handlers map[string]Handler
+	cron     *cron.Cron
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[int]cron.EntryID
+}
+
+// NewCronEngine returns a CronEngine that dispatches to handlers, keyed by Job.TargetEndpoint.
+func NewCronEngine(repo *JobRepo, handlers map[string]Handler) *CronEngine {
+	return &CronEngine{
+		repo:     repo,
+		handlers: handlers,
+		cron:     cron.New(),
+		now:      time.Now,
+		entries:  make(map[int]cron.EntryID),
+	}
+}
+
+// Start loads every active job and schedules it, then starts ticking in the background.
+// It returns an error if any job's cron expression or target endpoint is invalid.
+func (e *CronEngine) Start(ctx context.Context) error {
+	jobs, err := e.repo.List()
+	if err != nil {
+		return fmt.Errorf("load scheduled jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		if err := e.AddJob(job); err != nil {
+			return err
+		}
+	}
+
+	e.cron.Start()
+	return nil
+}
+
+// Stop blocks until any in-flight job finishes, then stops ticking.
+func (e *CronEngine) Stop() {
+	<-e.cron.Stop().Done()
+}
+
+// AddJob registers job with the running cron scheduler so it starts firing immediately,
+// without waiting for a process restart. It's a no-op if job isn't Active.
+func (e *CronEngine) AddJob(job Job) error {
+	if !job.Active {
+		return nil
+	}
+
+	handler, ok := e.handlers[job.TargetEndpoint]
+	if !ok {
+		return fmt.Errorf("job %q: no handler registered for target endpoint %q", job.Name, job.TargetEndpoint)
+	}
+
+	entryID, err := e.cron.AddFunc(job.CronExpr, func() { e.fire(job, handler) })
+	if err != nil {
+		return fmt.Errorf("job %q: invalid cron expression %q: %w", job.Name, job.CronExpr, err)
+	}
+
+	e.mu.Lock()
+	e.entries[job.ID] = entryID
+	e.mu.Unlock()
+
+	return nil
+}
+
+// RemoveJob unregisters jobID from the running cron scheduler so it stops firing
+// immediately, without waiting for a process restart. It's a no-op if jobID was never
+// registered, e.g. because it was never Active.
+func (e *CronEngine) RemoveJob(jobID int) error {
+	e.mu.Lock()
+	entryID, ok := e.entries[jobID]
+	delete(e.entries, jobID)
+	e.mu.Unlock()
+
+	if ok {
+		e.cron.Remove(entryID)
+	}
+
+	return nil
+}
+
+func (e *CronEngine) fire(job Job, handler Handler) {
+	status := "ok"
+	if err := handler(); err != nil {
+		status = err.Error()
+		log.Printf("scheduled job %q failed: %v", job.Name, err)
+	}
+
+	if err := e.repo.RecordRun(job.ID, e.now(), status); err != nil {
+		log.Printf("scheduled job %q: failed to record run: %v", job.Name, err)
+	}
+}
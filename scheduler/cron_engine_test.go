@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestJobRepo(t *testing.T) *JobRepo {
+	t.Helper()
+
+	path := "./test-scheduled-jobs.db"
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE scheduled_jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		cron_expr TEXT NOT NULL,
+		target_endpoint TEXT NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT 1,
+		last_run_at DATETIME,
+		last_status TEXT
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(path)
+	})
+
+	return NewJobRepo(db)
+}
+
+// TestCronEngineStartOnlySchedulesActiveJobs verifies that Start registers a cron entry
+// for every active job loaded from the repo, and skips inactive ones.
+func TestCronEngineStartOnlySchedulesActiveJobs(t *testing.T) {
+	repo := newTestJobRepo(t)
+	handlers := map[string]Handler{"noop": func() error { return nil }}
+
+	active, err := repo.Create(Job{Name: "active-job", CronExpr: "@every 1h", TargetEndpoint: "noop", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(Job{Name: "inactive-job", CronExpr: "@every 1h", TargetEndpoint: "noop", Active: false}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewCronEngine(repo, handlers)
+	if err := engine.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Stop()
+
+	if len(engine.cron.Entries()) != 1 {
+		t.Fatalf("expected 1 scheduled entry after Start, got %d", len(engine.cron.Entries()))
+	}
+	if _, ok := engine.entries[active.ID]; !ok {
+		t.Fatalf("expected active job %d to be tracked in entries", active.ID)
+	}
+}
+
+// TestCronEngineAddJobAndRemoveJob verifies that a job created after Start is registered
+// with the running scheduler immediately, and that RemoveJob unregisters it, so jobs
+// created or deleted through the live API take effect without a process restart.
+func TestCronEngineAddJobAndRemoveJob(t *testing.T) {
+	repo := newTestJobRepo(t)
+	handlers := map[string]Handler{"noop": func() error { return nil }}
+
+	engine := NewCronEngine(repo, handlers)
+	if err := engine.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Stop()
+
+	job, err := repo.Create(Job{Name: "late-job", CronExpr: "@every 1h", TargetEndpoint: "noop", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.AddJob(job); err != nil {
+		t.Fatalf("AddJob returned unexpected error: %v", err)
+	}
+	if len(engine.cron.Entries()) != 1 {
+		t.Fatalf("expected job added after Start to be scheduled, got %d entries", len(engine.cron.Entries()))
+	}
+
+	if err := engine.RemoveJob(job.ID); err != nil {
+		t.Fatalf("RemoveJob returned unexpected error: %v", err)
+	}
+	if len(engine.cron.Entries()) != 0 {
+		t.Fatalf("expected job to be unscheduled after RemoveJob, got %d entries", len(engine.cron.Entries()))
+	}
+}
+
+// TestCronEngineAddJobUnknownTargetEndpoint verifies AddJob rejects a job whose target
+// endpoint has no registered handler, instead of silently scheduling a dead tick.
+func TestCronEngineAddJobUnknownTargetEndpoint(t *testing.T) {
+	repo := newTestJobRepo(t)
+	engine := NewCronEngine(repo, map[string]Handler{})
+
+	err := engine.AddJob(Job{ID: 1, Name: "mystery-job", CronExpr: "@every 1h", TargetEndpoint: "unknown", Active: true})
+	if err == nil {
+		t.Fatal("expected AddJob to reject a job with an unregistered target endpoint")
+	}
+}
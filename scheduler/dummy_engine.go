@@ -0,0 +1,52 @@
+package scheduler
+
+import "context"
+
+// DummyEngine is an Engine that never ticks on its own; tests call Fire directly to
+// simulate a single cron tick without waiting on real time.
+type DummyEngine struct {
+	repo     *JobRepo
+	handlers map[string]Handler
+}
+
+// NewDummyEngine returns a DummyEngine that dispatches to handlers, keyed by Job.TargetEndpoint.
+func NewDummyEngine(repo *JobRepo, handlers map[string]Handler) *DummyEngine {
+	return &DummyEngine{repo: repo, handlers: handlers}
+}
+
+// Start is a no-op; DummyEngine only fires when Fire is called explicitly.
+func (e *DummyEngine) Start(ctx context.Context) error { return nil }
+
+// Stop is a no-op.
+func (e *DummyEngine) Stop() {}
+
+// AddJob is a no-op; DummyEngine always reads the current set of jobs from repo when Fire
+// is called, so there's no in-memory registration to update.
+func (e *DummyEngine) AddJob(job Job) error { return nil }
+
+// RemoveJob is a no-op, for the same reason as AddJob.
+func (e *DummyEngine) RemoveJob(jobID int) error { return nil }
+
+// Fire looks up the job by ID and invokes its registered handler once, as if its cron
+// expression had just ticked.
+func (e *DummyEngine) Fire(jobID int) error {
+	jobs, err := e.repo.List()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.ID != jobID {
+			continue
+		}
+
+		handler, ok := e.handlers[job.TargetEndpoint]
+		if !ok {
+			return &UnknownTargetError{TargetEndpoint: job.TargetEndpoint}
+		}
+
+		return handler()
+	}
+
+	return &JobNotFoundError{ID: jobID}
+}
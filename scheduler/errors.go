@@ -0,0 +1,21 @@
+package scheduler
+
+import "fmt"
+
+// JobNotFoundError is returned when a job ID has no matching row in scheduled_jobs.
+type JobNotFoundError struct {
+	ID int
+}
+
+func (e *JobNotFoundError) Error() string {
+	return fmt.Sprintf("job %d not found", e.ID)
+}
+
+// UnknownTargetError is returned when a job's target endpoint has no registered handler.
+type UnknownTargetError struct {
+	TargetEndpoint string
+}
+
+func (e *UnknownTargetError) Error() string {
+	return fmt.Sprintf("no handler registered for target endpoint %q", e.TargetEndpoint)
+}
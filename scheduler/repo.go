@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// JobRepo persists Jobs in the scheduled_jobs table.
+type JobRepo struct {
+	db *sql.DB
+}
+
+// NewJobRepo returns a JobRepo backed by db.
+func NewJobRepo(db *sql.DB) *JobRepo {
+	return &JobRepo{db: db}
+}
+
+// Create inserts job and returns it with its assigned ID.
+func (r *JobRepo) Create(job Job) (Job, error) {
+	res, err := r.db.Exec("INSERT INTO scheduled_jobs (name, cron_expr, target_endpoint, active) VALUES (?, ?, ?, ?)",
+		job.Name, job.CronExpr, job.TargetEndpoint, job.Active)
+	if err != nil {
+		return Job{}, fmt.Errorf("insert scheduled job: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Job{}, fmt.Errorf("read inserted job id: %w", err)
+	}
+	job.ID = int(id)
+
+	return job, nil
+}
+
+// List returns every job in the table, in insertion order.
+func (r *JobRepo) List() ([]Job, error) {
+	rows, err := r.db.Query("SELECT id, name, cron_expr, target_endpoint, active, last_run_at, last_status FROM scheduled_jobs ORDER BY id ASC")
+	if err != nil {
+		return nil, fmt.Errorf("query scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]Job, 0)
+	for rows.Next() {
+		var job Job
+		var lastRunAt sql.NullTime
+		var lastStatus sql.NullString
+		if err := rows.Scan(&job.ID, &job.Name, &job.CronExpr, &job.TargetEndpoint, &job.Active, &lastRunAt, &lastStatus); err != nil {
+			return nil, fmt.Errorf("scan scheduled job: %w", err)
+		}
+		if lastRunAt.Valid {
+			job.LastRunAt = &lastRunAt.Time
+		}
+		job.LastStatus = lastStatus.String
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Delete removes the job with the given ID.
+func (r *JobRepo) Delete(id int) error {
+	_, err := r.db.Exec("DELETE FROM scheduled_jobs WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete scheduled job %d: %w", id, err)
+	}
+	return nil
+}
+
+// RecordRun stamps job with the outcome of its most recent firing.
+func (r *JobRepo) RecordRun(id int, runAt time.Time, status string) error {
+	_, err := r.db.Exec("UPDATE scheduled_jobs SET last_run_at = ?, last_status = ? WHERE id = ?", runAt, status, id)
+	if err != nil {
+		return fmt.Errorf("record run for job %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Package scheduler fires registered handlers on a cron schedule, persisting
+// the job definitions in the scheduled_jobs table so they survive restarts.
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// Job represents a scheduled invocation of a registered handler.
+type Job struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	CronExpr       string     `json:"cron_expr"`
+	TargetEndpoint string     `json:"target_endpoint"`
+	Active         bool       `json:"active"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastStatus     string     `json:"last_status,omitempty"`
+}
+
+// Handler is fired when a job's cron expression ticks. TargetEndpoint identifies which
+// registered Handler a Job triggers.
+type Handler func() error
+
+// Engine runs every active Job on its cron schedule against a registered Handler.
+type Engine interface {
+	Start(ctx context.Context) error
+	Stop()
+
+	// AddJob registers job with the running engine so it starts firing immediately,
+	// without waiting for a process restart. It's a no-op if job isn't Active.
+	AddJob(job Job) error
+
+	// RemoveJob unregisters jobID from the running engine so it stops firing
+	// immediately, without waiting for a process restart.
+	RemoveJob(jobID int) error
+}
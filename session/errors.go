@@ -0,0 +1,10 @@
+package session
+
+import "errors"
+
+// ErrSessionKeyNotFound is returned by Pop when the key doesn't exist, either because it
+// was never pushed or because it has already been popped once.
+var ErrSessionKeyNotFound = errors.New("session key not found")
+
+// ErrSessionKeyExpired is returned by Pop when the key exists but its TTL has elapsed.
+var ErrSessionKeyExpired = errors.New("session key expired")
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+// clientIDContextKey is the context key Authenticate stores the resolved client ID under.
+const clientIDContextKey contextKey = "session.clientID"
+
+// Authenticate validates the "Authorization: Bearer <token>" header against repo and, on
+// success, injects the resolved client ID into the request context before calling next. The
+// token is checked, not consumed, so it keeps authenticating the client across repeated
+// calls until it expires.
+func Authenticate(repo *SessionKeyRepo) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+			token, ok := strings.CutPrefix(auth, "Bearer ")
+			if !ok || token == "" {
+				http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			clientID, err := repo.Validate(token)
+			if err != nil {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), clientIDContextKey, clientID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIDFromContext returns the client ID Authenticate resolved for this request, if any.
+func ClientIDFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(clientIDContextKey).(int)
+	return id, ok
+}
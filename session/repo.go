@@ -0,0 +1,93 @@
+// Package session implements expiring bearer tokens backed by SQLite, and an Authenticate
+// middleware that resolves them to the client that owns them.
+package session
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SessionKey is a bearer token minted for a specific client.
+type SessionKey struct {
+	Key      string
+	ClientID int
+}
+
+// SessionKeyRepo persists SessionKeys in the session_keys table.
+type SessionKeyRepo struct {
+	db *sql.DB
+}
+
+// NewSessionKeyRepo returns a SessionKeyRepo backed by db.
+func NewSessionKeyRepo(db *sql.DB) *SessionKeyRepo {
+	return &SessionKeyRepo{db: db}
+}
+
+// Push stores key, valid for ttl from now.
+func (r *SessionKeyRepo) Push(key SessionKey, ttl time.Duration) error {
+	_, err := r.db.Exec("INSERT INTO session_keys (key, client_id, expires_at) VALUES (?, ?, ?)",
+		key.Key, key.ClientID, time.Now().Add(ttl))
+	if err != nil {
+		return fmt.Errorf("push session key: %w", err)
+	}
+	return nil
+}
+
+// Validate checks key against the store and returns the client it was minted for, without
+// consuming it, so it can be used to guard a repeatedly-called endpoint. A key that doesn't
+// exist or has expired returns an error.
+func (r *SessionKeyRepo) Validate(key string) (int, error) {
+	row := r.db.QueryRow("SELECT client_id, expires_at FROM session_keys WHERE key = ?", key)
+	var clientID int
+	var expiresAt time.Time
+	if err := row.Scan(&clientID, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrSessionKeyNotFound
+		}
+		return 0, fmt.Errorf("scan session key: %w", err)
+	}
+
+	if time.Now().After(expiresAt) {
+		return 0, ErrSessionKeyExpired
+	}
+
+	return clientID, nil
+}
+
+// Pop validates key, removes it so it can never be used again, and returns the client it
+// was minted for. A key that doesn't exist, has already been popped, or has expired
+// returns an error.
+func (r *SessionKeyRepo) Pop(key string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin pop session key: %w", err)
+	}
+
+	row := tx.QueryRow("SELECT client_id, expires_at FROM session_keys WHERE key = ?", key)
+	var clientID int
+	var expiresAt time.Time
+	if err := row.Scan(&clientID, &expiresAt); err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrSessionKeyNotFound
+		}
+		return 0, fmt.Errorf("scan session key: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM session_keys WHERE key = ?", key); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("delete session key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit pop session key: %w", err)
+	}
+
+	if time.Now().After(expiresAt) {
+		return 0, ErrSessionKeyExpired
+	}
+
+	return clientID, nil
+}
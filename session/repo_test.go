@@ -0,0 +1,111 @@
+package session
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestRepo(t *testing.T) *SessionKeyRepo {
+	t.Helper()
+
+	path := "./test-session-keys.db"
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE session_keys (key TEXT PRIMARY KEY, client_id INTEGER, expires_at DATETIME)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(path)
+	})
+
+	return NewSessionKeyRepo(db)
+}
+
+// TestDBSessionKeyRepoPushPop verifies the single-use semantics of Pop: a key can be
+// redeemed once, and a second Pop for the same key fails.
+func TestDBSessionKeyRepoPushPop(t *testing.T) {
+	repo := newTestRepo(t)
+
+	if err := repo.Push(SessionKey{Key: "abc", ClientID: 42}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	clientID, err := repo.Pop("abc")
+	if err != nil {
+		t.Fatalf("first Pop returned unexpected error: %v", err)
+	}
+	if clientID != 42 {
+		t.Fatalf("client ID mismatch, got: %v, want: %v", clientID, 42)
+	}
+
+	if _, err := repo.Pop("abc"); !errors.Is(err, ErrSessionKeyNotFound) {
+		t.Fatalf("second Pop error, got: %v, want: %v", err, ErrSessionKeyNotFound)
+	}
+}
+
+// TestDBSessionKeyRepoValidateDoesNotConsume verifies that Validate can check the same key
+// repeatedly without ever invalidating it, unlike Pop.
+func TestDBSessionKeyRepoValidateDoesNotConsume(t *testing.T) {
+	repo := newTestRepo(t)
+
+	if err := repo.Push(SessionKey{Key: "abc", ClientID: 42}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		clientID, err := repo.Validate("abc")
+		if err != nil {
+			t.Fatalf("Validate call %d returned unexpected error: %v", i+1, err)
+		}
+		if clientID != 42 {
+			t.Fatalf("client ID mismatch, got: %v, want: %v", clientID, 42)
+		}
+	}
+}
+
+// TestDBSessionKeyRepoValidateExpired verifies that Validate rejects a key whose TTL has
+// elapsed, without consuming it.
+func TestDBSessionKeyRepoValidateExpired(t *testing.T) {
+	repo := newTestRepo(t)
+
+	if err := repo.Push(SessionKey{Key: "expired", ClientID: 7}, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Validate("expired"); !errors.Is(err, ErrSessionKeyExpired) {
+		t.Fatalf("Validate error, got: %v, want: %v", err, ErrSessionKeyExpired)
+	}
+}
+
+// TestDBSessionKeyRepoPopExpired verifies that Pop rejects a key whose TTL has elapsed,
+// even though it still consumes it.
+func TestDBSessionKeyRepoPopExpired(t *testing.T) {
+	repo := newTestRepo(t)
+
+	if err := repo.Push(SessionKey{Key: "expired", ClientID: 7}, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Pop("expired"); !errors.Is(err, ErrSessionKeyExpired) {
+		t.Fatalf("Pop error, got: %v, want: %v", err, ErrSessionKeyExpired)
+	}
+
+	if _, err := repo.Pop("expired"); !errors.Is(err, ErrSessionKeyNotFound) {
+		t.Fatalf("Pop after expiry consumed, got: %v, want: %v", err, ErrSessionKeyNotFound)
+	}
+}
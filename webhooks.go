@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/FreelancerDenial/simpleAdvertisingCRM/dispatcher"
+)
+
+// healthProbeInterval is how often unhealthy clients are re-probed.
+const healthProbeInterval = time.Minute
+
+// webhookDispatcher delivers assigned-lead payloads to client webhooks; healthRepo tracks
+// which clients are currently unhealthy so doAssignLead can skip them.
+var webhookDispatcher *dispatcher.Dispatcher
+var healthRepo *dispatcher.HealthRepo
+
+// setupDispatcher wires a Dispatcher and HealthRepo to db and starts the background
+// healthchecker that re-probes unhealthy clients' webhooks.
+func setupDispatcher(ctx context.Context) {
+	healthRepo = dispatcher.NewHealthRepo(db)
+	webhookDispatcher = dispatcher.NewDispatcher(healthRepo)
+
+	checker := dispatcher.NewHealthChecker(healthRepo, dispatcher.DefaultTimeout, listWebhookTargets)
+	checker.Start(ctx, healthProbeInterval)
+}
+
+// listWebhookTargets returns every client's webhook endpoint for the healthchecker to probe.
+func listWebhookTargets() ([]dispatcher.Target, error) {
+	rows, err := db.Query("SELECT id, webhook_url FROM clients WHERE webhook_url != ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []dispatcher.Target
+	for rows.Next() {
+		var target dispatcher.Target
+		if err := rows.Scan(&target.ClientID, &target.Endpoint); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, rows.Err()
+}
+
+// retrieveClientHealth handles a GET request for the health state of the client identified
+// by the "id" path parameter.
+func retrieveClientHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Client ID is missing", http.StatusBadRequest)
+		return
+	}
+
+	row := db.QueryRow("SELECT id FROM clients WHERE uuid = ?", id)
+	var internalID int
+	if err := row.Scan(&internalID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Client not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to look up client", http.StatusInternalServerError)
+			log.Println(err)
+		}
+		return
+	}
+
+	health, err := healthRepo.Get(internalID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve client health", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(health)
+}